@@ -0,0 +1,85 @@
+// Package messages builds the null-byte-delimited strings exchanged between
+// zeusclient and the zeus master over the control unix socket.
+package messages
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CreateCommandAndArgumentsMessage builds the initial message a client
+// sends to the master to start a command: which command to run, and the
+// pid the master should associate with this client's fds.
+func CreateCommandAndArgumentsMessage(args []string, pid int) string {
+	return fmt.Sprintf("command\000%d\000%s", pid, strings.Join(args, "\000"))
+}
+
+// CreateFramedCommandAndArgumentsMessage is CreateCommandAndArgumentsMessage,
+// but negotiates framed mode: the master multiplexes stdout, stderr, and the
+// final exit status as length-prefixed frames over a single fd instead of
+// handing back raw pty/socketpair fds plus a trailing control message.
+func CreateFramedCommandAndArgumentsMessage(args []string, pid int) string {
+	return fmt.Sprintf("command-framed\000%d\000%s", pid, strings.Join(args, "\000"))
+}
+
+// Which identifies the stdout or stderr pty a WindowSizeMessage applies to.
+type Which int
+
+const (
+	Stdout Which = iota
+	Stderr
+)
+
+func (w Which) String() string {
+	if w == Stderr {
+		return "stderr"
+	}
+	return "stdout"
+}
+
+// WindowSizeMessage carries a terminal size update out-of-band from
+// SIGWINCH, so non-tty front-ends (a gRPC client, a web terminal) can push
+// resizes too.
+type WindowSizeMessage struct {
+	Cols   uint16
+	Rows   uint16
+	XPixel uint16
+	YPixel uint16
+	Which  Which
+}
+
+// CreateWindowSizeMessage serializes a WindowSizeMessage for the control
+// socket.
+func CreateWindowSizeMessage(m WindowSizeMessage) string {
+	return fmt.Sprintf("winsize\000%s\000%d\000%d\000%d\000%d",
+		m.Which, m.Cols, m.Rows, m.XPixel, m.YPixel)
+}
+
+// ParseWindowSizeMessage parses a message produced by
+// CreateWindowSizeMessage back into a WindowSizeMessage. parts is the
+// message already split on "\000", with parts[0] == "winsize".
+func ParseWindowSizeMessage(parts []string) (WindowSizeMessage, error) {
+	var m WindowSizeMessage
+	if len(parts) != 6 || parts[0] != "winsize" {
+		return m, fmt.Errorf("messages: malformed window size message: %q", parts)
+	}
+	switch parts[1] {
+	case "stderr":
+		m.Which = Stderr
+	case "stdout":
+		m.Which = Stdout
+	default:
+		return m, fmt.Errorf("messages: malformed window size message: unknown stream %q", parts[1])
+	}
+
+	fields := []*uint16{&m.Cols, &m.Rows, &m.XPixel, &m.YPixel}
+	for i, f := range fields {
+		n, err := strconv.ParseUint(parts[2+i], 10, 16)
+		if err != nil {
+			return m, err
+		}
+		*f = uint16(n)
+	}
+	return m, nil
+}
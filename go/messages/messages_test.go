@@ -0,0 +1,42 @@
+package messages
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWindowSizeMessageRoundTrip(t *testing.T) {
+	cases := []WindowSizeMessage{
+		{Cols: 80, Rows: 24, XPixel: 0, YPixel: 0, Which: Stdout},
+		{Cols: 200, Rows: 55, XPixel: 1024, YPixel: 768, Which: Stderr},
+	}
+
+	for _, want := range cases {
+		msg := CreateWindowSizeMessage(want)
+		got, err := ParseWindowSizeMessage(strings.Split(msg, "\000"))
+		if err != nil {
+			t.Fatalf("ParseWindowSizeMessage(%q): %v", msg, err)
+		}
+		if got != want {
+			t.Errorf("round trip of %+v = %+v", want, got)
+		}
+	}
+}
+
+func TestParseWindowSizeMessageMalformed(t *testing.T) {
+	cases := [][]string{
+		nil,
+		{"winsize", "stdout", "80", "24", "0"}, // too few fields
+		{"notwinsize", "stdout", "80", "24", "0", "0"},      // wrong tag
+		{"winsize", "stdout", "notanumber", "24", "0", "0"}, // non-numeric field
+		{"winsize", "stdout", "-1", "24", "0", "0"},         // out of uint16 range
+		{"winsize", "stdout", "65536", "24", "0", "0"},      // overflows uint16
+		{"winsize", "sideways", "80", "24", "0", "0"},       // unknown stream
+	}
+
+	for _, parts := range cases {
+		if _, err := ParseWindowSizeMessage(parts); err == nil {
+			t.Errorf("ParseWindowSizeMessage(%q): expected error, got nil", parts)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package zeusclient
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// FrameStream identifies which logical stream a Frame carries.
+type FrameStream uint8
+
+const (
+	FrameStdout FrameStream = iota
+	FrameStderr
+	FrameStdin
+	// FrameExit is the terminal frame: it carries the remote command's exit
+	// code and, if it died from a signal, that signal number, and no
+	// payload of its own.
+	FrameExit
+)
+
+// Frame is one length-prefixed message on a framed-mode Session's master
+// socket: a one-byte stream id, a four-byte big-endian payload length, and
+// the payload itself.
+type Frame struct {
+	Stream  FrameStream
+	Payload []byte
+
+	// ExitCode and ExitSignal are only meaningful when Stream == FrameExit.
+	ExitCode   int32
+	ExitSignal int32
+}
+
+// FrameHandler receives each frame RunFramed reads from a session, in
+// order, up to and including the final FrameExit frame.
+type FrameHandler func(Frame)
+
+const frameHeaderSize = 5 // 1 byte stream id + 4 byte big-endian length
+
+// maxFrameLength bounds the length field of an incoming frame so a
+// corrupted or malicious header can't trigger an unbounded allocation.
+const maxFrameLength = 64 << 20 // 64MiB
+
+func writeFrame(w io.Writer, f Frame) error {
+	payload := f.Payload
+	if f.Stream == FrameExit {
+		payload = make([]byte, 8)
+		binary.BigEndian.PutUint32(payload[0:4], uint32(f.ExitCode))
+		binary.BigEndian.PutUint32(payload[4:8], uint32(f.ExitSignal))
+	}
+
+	header := make([]byte, frameHeaderSize)
+	header[0] = byte(f.Stream)
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func readFrame(r io.Reader) (Frame, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return Frame{}, err
+	}
+
+	f := Frame{Stream: FrameStream(header[0])}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > maxFrameLength {
+		return Frame{}, fmt.Errorf("zeusclient: frame length %d exceeds max %d", length, maxFrameLength)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return Frame{}, err
+	}
+
+	if f.Stream == FrameExit {
+		if len(payload) != 8 {
+			return Frame{}, fmt.Errorf("zeusclient: malformed exit frame: want 8 byte payload, got %d", len(payload))
+		}
+		f.ExitCode = int32(binary.BigEndian.Uint32(payload[0:4]))
+		f.ExitSignal = int32(binary.BigEndian.Uint32(payload[4:8]))
+	} else {
+		f.Payload = payload
+	}
+	return f, nil
+}
@@ -0,0 +1,74 @@
+package zeusclient
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWriteReadFrameRoundTrip(t *testing.T) {
+	cases := []Frame{
+		{Stream: FrameStdout, Payload: []byte("hello")},
+		{Stream: FrameStderr, Payload: []byte{}},
+		{Stream: FrameStdin, Payload: []byte("input\x00with\x00nulls")},
+		{Stream: FrameExit, ExitCode: 17, ExitSignal: 0},
+		{Stream: FrameExit, ExitCode: 0, ExitSignal: 9},
+	}
+
+	for _, want := range cases {
+		var buf bytes.Buffer
+		if err := writeFrame(&buf, want); err != nil {
+			t.Fatalf("writeFrame(%+v): %v", want, err)
+		}
+
+		got, err := readFrame(&buf)
+		if err != nil {
+			t.Fatalf("readFrame after writeFrame(%+v): %v", want, err)
+		}
+
+		if got.Stream != want.Stream {
+			t.Errorf("Stream = %v, want %v", got.Stream, want.Stream)
+		}
+		if want.Stream == FrameExit {
+			if got.ExitCode != want.ExitCode || got.ExitSignal != want.ExitSignal {
+				t.Errorf("ExitCode/ExitSignal = %d/%d, want %d/%d", got.ExitCode, got.ExitSignal, want.ExitCode, want.ExitSignal)
+			}
+		} else if !bytes.Equal(got.Payload, want.Payload) {
+			t.Errorf("Payload = %q, want %q", got.Payload, want.Payload)
+		}
+	}
+}
+
+func TestReadFrameTruncatedHeader(t *testing.T) {
+	buf := bytes.NewReader([]byte{0, 0, 1})
+	if _, err := readFrame(buf); err != io.ErrUnexpectedEOF {
+		t.Errorf("readFrame with truncated header: got err %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestReadFrameTruncatedPayload(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeFrame(&buf, Frame{Stream: FrameStdout, Payload: []byte("hello")}); err != nil {
+		t.Fatalf("writeFrame: %v", err)
+	}
+
+	truncated := bytes.NewReader(buf.Bytes()[:frameHeaderSize+2])
+	if _, err := readFrame(truncated); err != io.ErrUnexpectedEOF {
+		t.Errorf("readFrame with truncated payload: got err %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestReadFrameShortExitPayload(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{byte(FrameExit), 0, 0, 0, 0})
+	if _, err := readFrame(buf); err == nil {
+		t.Error("readFrame with zero-length exit frame: got nil error, want error")
+	}
+}
+
+func TestReadFrameOversizedLength(t *testing.T) {
+	header := []byte{byte(FrameStdout), 0xff, 0xff, 0xff, 0xff}
+	buf := bytes.NewBuffer(header)
+	if _, err := readFrame(buf); err == nil {
+		t.Error("readFrame with oversized length: got nil error, want error")
+	}
+}
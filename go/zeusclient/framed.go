@@ -0,0 +1,120 @@
+package zeusclient
+
+import (
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/burke/zeus/go/messages"
+	slog "github.com/burke/zeus/go/shinylog"
+	"github.com/burke/zeus/go/unixsocket"
+	"github.com/burke/zeus/go/zerror"
+)
+
+// newFramedSession dials the master like NewSession, but negotiates framed
+// mode (see frame.go): stdout, stderr, and the exit status all come back
+// multiplexed as Frames over a single socketpair.
+func newFramedSession(args []string) (*Session, error) {
+	master, slave, err := unixsocket.Socketpair(syscall.SOCK_STREAM)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := net.ResolveUnixAddr("unixgram", unixsocket.ZeusSockName())
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	conn, err := net.DialUnix("unix", nil, addr)
+	if err != nil {
+		master.Close()
+		return nil, ErrCantConnectToMaster
+	}
+	usock := unixsocket.New(conn)
+
+	msg := messages.CreateFramedCommandAndArgumentsMessage(args, os.Getpid())
+	usock.WriteMessage(msg)
+	if err := sendCommandLineArguments(usock, args); err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	usock.WriteFD(int(slave.Fd()))
+	slave.Close()
+
+	msg, err = usock.ReadMessage()
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	parts := strings.Split(msg, "\000")
+	commandPid, err := strconv.Atoi(parts[0])
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	return &Session{
+		Master:            master,
+		CommandPid:        commandPid,
+		InitialExitStatus: -1,
+		usock:             usock,
+	}, nil
+}
+
+// RunFramed runs a zeus command in framed mode: stdin is sent as FrameStdin
+// frames, and every frame the master sends back (FrameStdout, FrameStderr,
+// and the terminal FrameExit) is handed to handler in order.
+func RunFramed(args []string, stdin io.Reader, handler FrameHandler) int {
+	session, err := newFramedSession(args)
+	if err != nil {
+		if err == ErrCantConnectToMaster {
+			zerror.ErrorCantConnectToMaster()
+		} else {
+			slog.ErrorString(err.Error())
+		}
+		return 1
+	}
+	defer session.Close()
+	defer func() {
+		if session.CommandPid > 0 {
+			// Just in case.
+			syscall.Kill(session.CommandPid, 9)
+		}
+	}()
+
+	go func() {
+		buf := copyBufferPool.Get().([]byte)
+		defer copyBufferPool.Put(buf)
+		for {
+			n, err := stdin.Read(buf)
+			if n > 0 {
+				payload := make([]byte, n)
+				copy(payload, buf[:n])
+				if werr := writeFrame(session.Master, Frame{Stream: FrameStdin, Payload: payload}); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		f, err := readFrame(session.Master)
+		if err != nil {
+			slog.ErrorString(err.Error())
+			return 1
+		}
+		handler(f)
+		if f.Stream == FrameExit {
+			return int(f.ExitCode)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package zeusclient
+
+import (
+	"io"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// copyBufferPool holds the buffers used to pump master<->output,
+// master<->stderr, and input<->master (and RunFramed's stdin pump), so they
+// don't show up in GC pressure. The gRPC shim's own pumps don't use it.
+var copyBufferPool = sync.Pool{
+	New: func() interface{} { return make([]byte, 32*1024) },
+}
+
+func copyPooled(dst io.Writer, src io.Reader) (int64, error) {
+	buf := copyBufferPool.Get().([]byte)
+	defer copyBufferPool.Put(buf)
+	return io.CopyBuffer(dst, src, buf)
+}
+
+// signalScanningReader wraps stdin and translates the in-band ^C/^\/^Z bytes
+// a terminal sends into signals for the remote command.
+type signalScanningReader struct {
+	r       io.Reader
+	session *Session
+}
+
+func (r *signalScanningReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for i := 0; i < n; i++ {
+		switch p[i] {
+		case sigInt:
+			r.session.Signal(syscall.SIGINT)
+		case sigQuit:
+			r.session.Signal(syscall.SIGQUIT)
+		case sigTstp:
+			r.session.Signal(syscall.SIGTSTP)
+			syscall.Kill(os.Getpid(), syscall.SIGTSTP)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,217 @@
+package zeusclient
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/burke/zeus/go/messages"
+	slog "github.com/burke/zeus/go/shinylog"
+	"github.com/burke/zeus/go/unixsocket"
+	"github.com/kr/pty"
+)
+
+// ErrCantConnectToMaster is returned by NewSession when the zeus master
+// socket can't be reached at all (as opposed to an error partway through an
+// otherwise-successful handshake).
+var ErrCantConnectToMaster = errors.New("zeusclient: can't connect to zeus master")
+
+// Session is the client-side end of a single zeus command invocation: the
+// pty (or socketpair) fds connecting to the remote command's stdout and
+// stderr, the unix socket to the zeus master, and the remote command's pid.
+//
+// Session only knows how to talk to the master; it has no opinion about
+// where the bytes it shuttles come from or go to. Run (the CLI entrypoint)
+// and the gRPC shim server both construct a Session and drive it the same
+// way, wiring it to a real terminal or to an RPC stream respectively.
+type Session struct {
+	Master       *os.File
+	MasterStderr *os.File
+	CommandPid   int
+
+	// InitialExitStatus is set when the master's initial pid message
+	// happens to carry a third, null-delimited field: the remote command's
+	// exit status, for the case where it has already exited by the time
+	// the handshake message is read. -1 means "not yet known", i.e. the
+	// caller must still read it later via Wait.
+	InitialExitStatus int
+
+	useTTY bool
+	usock  *unixsocket.Usock
+}
+
+// NewSession dials the zeus master, sends the command and its arguments, and
+// hands it the slave end of a pty (if useTTY) or a socketpair for stdout and
+// stderr. It blocks until the master reports the remote command's pid.
+func NewSession(args []string, useTTY bool) (*Session, error) {
+	master, slave, err := openMasterSlave(useTTY)
+	if err != nil {
+		return nil, err
+	}
+
+	masterStderr, slaveStderr, err := openMasterSlave(useTTY)
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	addr, err := net.ResolveUnixAddr("unixgram", unixsocket.ZeusSockName())
+	if err != nil {
+		master.Close()
+		masterStderr.Close()
+		return nil, err
+	}
+
+	conn, err := net.DialUnix("unix", nil, addr)
+	if err != nil {
+		master.Close()
+		masterStderr.Close()
+		return nil, ErrCantConnectToMaster
+	}
+	usock := unixsocket.New(conn)
+
+	msg := messages.CreateCommandAndArgumentsMessage(args, os.Getpid())
+	usock.WriteMessage(msg)
+	if err := sendCommandLineArguments(usock, args); err != nil {
+		master.Close()
+		masterStderr.Close()
+		return nil, err
+	}
+
+	usock.WriteFD(int(slave.Fd()))
+	slave.Close()
+
+	usock.WriteFD(int(slaveStderr.Fd()))
+	slaveStderr.Close()
+
+	msg, err = usock.ReadMessage()
+	if err != nil {
+		master.Close()
+		masterStderr.Close()
+		return nil, err
+	}
+
+	parts := strings.Split(msg, "\000")
+	commandPid, err := strconv.Atoi(parts[0])
+	if err != nil {
+		master.Close()
+		masterStderr.Close()
+		return nil, err
+	}
+
+	initialExitStatus := -1
+	if len(parts) > 2 {
+		initialExitStatus, err = strconv.Atoi(parts[2])
+		if err != nil {
+			master.Close()
+			masterStderr.Close()
+			return nil, err
+		}
+	}
+
+	return &Session{
+		Master:            master,
+		MasterStderr:      masterStderr,
+		CommandPid:        commandPid,
+		InitialExitStatus: initialExitStatus,
+		useTTY:            useTTY,
+		usock:             usock,
+	}, nil
+}
+
+func openMasterSlave(useTTY bool) (master, slave *os.File, err error) {
+	if useTTY {
+		return pty.Open()
+	}
+	return unixsocket.Socketpair(syscall.SOCK_STREAM)
+}
+
+// Signal forwards a signal to the remote command.
+func (s *Session) Signal(sig syscall.Signal) error {
+	return syscall.Kill(s.CommandPid, sig)
+}
+
+// Resize pushes a new terminal size for the given stream: directly via
+// TIOCSWINSZ on the local master fd when useTTY, and always via a
+// WindowSizeMessage so non-tty front-ends can propagate size too.
+func (s *Session) Resize(which messages.Which, cols, rows, xpixel, ypixel uint16) error {
+	if s.useTTY {
+		fd := s.Master.Fd()
+		if which == messages.Stderr {
+			fd = s.MasterStderr.Fd()
+		}
+		if err := setWinsize(fd, winsize{Rows: rows, Cols: cols, XPixel: xpixel, YPixel: ypixel}); err != nil {
+			return err
+		}
+	}
+
+	s.usock.WriteMessage(messages.CreateWindowSizeMessage(messages.WindowSizeMessage{
+		Cols: cols, Rows: rows, XPixel: xpixel, YPixel: ypixel, Which: which,
+	}))
+	return nil
+}
+
+// ResizeFromFile reads the current window size of f (typically the CLI's
+// own stdout or stderr) and pushes it via Resize.
+func (s *Session) ResizeFromFile(which messages.Which, f *os.File) error {
+	ws, err := getWinsize(f.Fd())
+	if err != nil {
+		return err
+	}
+	return s.Resize(which, ws.Cols, ws.Rows, ws.XPixel, ws.YPixel)
+}
+
+// Wait blocks until the master sends the remote command's final exit
+// status.
+func (s *Session) Wait() (int, error) {
+	msg, err := s.usock.ReadMessage()
+	if err != nil {
+		return -1, err
+	}
+	parts := strings.Split(msg, "\000")
+	return strconv.Atoi(parts[0])
+}
+
+// Close releases the master fds. It does not signal the remote command;
+// callers that need to make sure the command is gone should Signal it
+// themselves first.
+func (s *Session) Close() error {
+	s.Master.Close()
+	// A framed Session multiplexes stdout and stderr onto Master alone.
+	if s.MasterStderr != nil {
+		s.MasterStderr.Close()
+	}
+	return nil
+}
+
+func sendCommandLineArguments(usock *unixsocket.Usock, args []string) error {
+	master, slave, err := unixsocket.Socketpair(syscall.SOCK_STREAM)
+	if err != nil {
+		return err
+	}
+	usock.WriteFD(int(slave.Fd()))
+	if err != nil {
+		return err
+	}
+	slave.Close()
+
+	go func() {
+		defer master.Close()
+		argAsBytes := []byte{}
+		for _, arg := range args[1:] {
+			argAsBytes = append(argAsBytes, []byte(arg)...)
+			argAsBytes = append(argAsBytes, byte(0))
+		}
+		_, err = master.Write(argAsBytes)
+		if err != nil {
+			slog.ErrorString("Could not send arguments across: " +
+				err.Error() + "\r")
+			return
+		}
+	}()
+
+	return nil
+}
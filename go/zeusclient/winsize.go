@@ -0,0 +1,30 @@
+package zeusclient
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	Rows   uint16
+	Cols   uint16
+	XPixel uint16
+	YPixel uint16
+}
+
+func getWinsize(fd uintptr) (winsize, error) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return ws, errno
+	}
+	return ws, nil
+}
+
+func setWinsize(fd uintptr, ws winsize) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
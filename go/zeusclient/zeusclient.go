@@ -2,20 +2,16 @@ package zeusclient
 
 import (
 	"io"
-	"net"
 	"os"
 	"os/signal"
-	"strconv"
-	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/burke/ttyutils"
 	"github.com/burke/zeus/go/messages"
 	slog "github.com/burke/zeus/go/shinylog"
-	"github.com/burke/zeus/go/unixsocket"
 	"github.com/burke/zeus/go/zerror"
-	"github.com/kr/pty"
 )
 
 const (
@@ -24,9 +20,61 @@ const (
 	sigTstp = 26
 )
 
+// gracefulShutdownTimeout bounds how long we wait for a clean exit after
+// SIGHUP before force-killing the remote command.
+const gracefulShutdownTimeout = 5 * time.Second
+
 // man signal | grep 'terminate process' | awk '{print $2}' | xargs -I '{}' echo -n "syscall.{}, "
-var terminatingSignals = []os.Signal{syscall.SIGHUP, syscall.SIGINT, syscall.SIGKILL, syscall.SIGPIPE, syscall.SIGALRM, syscall.SIGTERM, syscall.SIGXCPU, syscall.SIGXFSZ, syscall.SIGVTALRM, syscall.SIGPROF, syscall.SIGUSR1, syscall.SIGUSR2}
+// SIGHUP is handled separately, see gracefulShutdownTimeout.
+var terminatingSignals = []os.Signal{syscall.SIGINT, syscall.SIGKILL, syscall.SIGPIPE, syscall.SIGALRM, syscall.SIGTERM, syscall.SIGXCPU, syscall.SIGXFSZ, syscall.SIGVTALRM, syscall.SIGPROF, syscall.SIGUSR1, syscall.SIGUSR2}
+
+// nonTerminalSignals is the reduced set we forward when zeus has no
+// controlling tty of its own (e.g. `zeus test foo | tee log`).
+var nonTerminalSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP, syscall.SIGTSTP, syscall.SIGCONT, syscall.SIGWINCH}
+
+// tstpAckGrace is how long we give the remote command to react to a
+// forwarded SIGTSTP before we stop ourselves; there's no ack message for it.
+const tstpAckGrace = 100 * time.Millisecond
+
+// suspendSelf forwards SIGTSTP to the remote command and then suspends the
+// zeus client itself, so `^Z` still works as job control with no controlling
+// terminal. signal.Notify stays registered across the self-SIGSTOP so a
+// signal arriving while suspended is queued instead of killing the process
+// outright on resume.
+func suspendSelf(session *Session, reassertTerminalState func()) {
+	session.Signal(syscall.SIGTSTP)
+	time.Sleep(tstpAckGrace)
+
+	syscall.Kill(os.Getpid(), syscall.SIGSTOP)
+	// ... execution resumes here once the shell sends us SIGCONT ...
+	if reassertTerminalState != nil {
+		reassertTerminalState()
+	}
+
+	session.Signal(syscall.SIGCONT)
+}
+
+// forwardSIGHUP forwards SIGHUP to the remote command. Shared by the
+// terminal and non-terminal signal-handling goroutines in Run.
+func forwardSIGHUP(session *Session, endOfIODone <-chan struct{}) {
+	forwardAndAwaitDrain(session, syscall.SIGHUP, endOfIODone)
+}
+
+// forwardAndAwaitDrain forwards sig, then gives the remote command until
+// gracefulShutdownTimeout to exit and drain before escalating to SIGKILL,
+// instead of os.Exit bypassing Run's own deferred cleanup.
+func forwardAndAwaitDrain(session *Session, sig syscall.Signal, endOfIODone <-chan struct{}) {
+	session.Signal(sig)
+	select {
+	case <-endOfIODone:
+	case <-time.After(gracefulShutdownTimeout):
+		session.Signal(syscall.SIGKILL)
+	}
+}
 
+// Run is the CLI entrypoint: it owns this process's real stdio/tty, drives a
+// Session against the zeus master, and blocks until the remote command
+// exits. Programmatic callers should construct a Session directly instead.
 func Run(args []string, input io.Reader, output *os.File, stderr *os.File) int {
 	if os.Getenv("RAILS_ENV") != "" {
 		println("Warning: Specifying a Rails environment via RAILS_ENV has no effect for commands run with zeus.")
@@ -35,48 +83,25 @@ func Run(args []string, input io.Reader, output *os.File, stderr *os.File) int {
 		return 1
 	}
 
-	// setup stdout
 	isTerminal := ttyutils.IsTerminal(output.Fd())
-
-	var master, slave *os.File
-
-	if isTerminal {
-		var err error
-		master, slave, err = pty.Open()
-		if err != nil {
-			slog.ErrorString(err.Error() + "\r")
-			return 1
-		}
-	} else {
-		var err error
-		master, slave, err = unixsocket.Socketpair(syscall.SOCK_STREAM)
-		if err != nil {
-			slog.ErrorString(err.Error() + "\r")
-			return 1
-		}
-	}
-	defer master.Close()
-
-	// setup stderr
 	stderrIsTerminal := ttyutils.IsTerminal(stderr.Fd())
 
-	var masterStderr, slaveStderr *os.File
-	if isTerminal {
-		var err error
-		masterStderr, slaveStderr, err = pty.Open()
-		if err != nil {
-			slog.ErrorString(err.Error() + "\r")
-			return 1
-		}
-	} else {
-		var err error
-		masterStderr, slaveStderr, err = unixsocket.Socketpair(syscall.SOCK_STREAM)
-		if err != nil {
+	session, err := NewSession(args, isTerminal)
+	if err != nil {
+		if err == ErrCantConnectToMaster {
+			zerror.ErrorCantConnectToMaster()
+		} else {
 			slog.ErrorString(err.Error() + "\r")
-			return 1
 		}
+		return 1
 	}
-	defer masterStderr.Close()
+	defer session.Close()
+	defer func() {
+		if session.CommandPid > 0 {
+			// Just in case.
+			syscall.Kill(session.CommandPid, 9)
+		}
+	}()
 
 	// setup terminal for stdout
 	var oldState *ttyutils.Termios
@@ -106,153 +131,95 @@ func Run(args []string, input io.Reader, output *os.File, stderr *os.File) int {
 	}
 
 	// should this happen if we're running over a pipe? I think maybe not?
-	ttyutils.MirrorWinsize(output, master)
-	ttyutils.MirrorWinsize(stderr, masterStderr)
-
-	addr, err := net.ResolveUnixAddr("unixgram", unixsocket.ZeusSockName())
-	if err != nil {
-		slog.ErrorString(err.Error() + "\r")
-		return 1
-	}
-
-	conn, err := net.DialUnix("unix", nil, addr)
-	if err != nil {
-		zerror.ErrorCantConnectToMaster()
-		return 1
-	}
-	usock := unixsocket.New(conn)
-
-	msg := messages.CreateCommandAndArgumentsMessage(args, os.Getpid())
-	usock.WriteMessage(msg)
-	err = sendCommandLineArguments(usock, args)
-	if err != nil {
-		slog.ErrorString(err.Error() + "\r")
-		return 1
-	}
-
-	usock.WriteFD(int(slave.Fd()))
-	slave.Close()
+	session.ResizeFromFile(messages.Stdout, output)
+	session.ResizeFromFile(messages.Stderr, stderr)
 
-	usock.WriteFD(int(slaveStderr.Fd()))
-	slaveStderr.Close()
-
-	msg, err = usock.ReadMessage()
-	if err != nil {
-		slog.ErrorString(err.Error() + "\r")
-		return 1
-	}
-
-	parts := strings.Split(msg, "\000")
-	commandPid, err := strconv.Atoi(parts[0])
-	defer func() {
-		if commandPid > 0 {
-			// Just in case.
-			syscall.Kill(commandPid, 9)
-		}
-	}()
-
-	if err != nil {
-		slog.ErrorString(err.Error() + "\r")
-		return 1
-	}
+	endOfIODone := make(chan struct{})
 
 	if isTerminal {
 		c := make(chan os.Signal, 1)
-		handledSignals := append(append(terminatingSignals, syscall.SIGWINCH), syscall.SIGCONT)
+		handledSignals := append(append(append(terminatingSignals, syscall.SIGWINCH), syscall.SIGCONT), syscall.SIGHUP)
 		signal.Notify(c, handledSignals...)
 		go func() {
 			for sig := range c {
 				if sig == syscall.SIGCONT {
-					syscall.Kill(commandPid, syscall.SIGCONT)
+					session.Signal(syscall.SIGCONT)
 				} else if sig == syscall.SIGWINCH {
-					ttyutils.MirrorWinsize(output, master)
-					syscall.Kill(commandPid, syscall.SIGWINCH)
+					// Resize both streams: they may be different ttys, and
+					// the kernel only delivers one SIGWINCH for either.
+					session.ResizeFromFile(messages.Stdout, output)
+					if stderrIsTerminal {
+						session.ResizeFromFile(messages.Stderr, stderr)
+					}
+					session.Signal(syscall.SIGWINCH)
+				} else if sig == syscall.SIGHUP {
+					// Give the command a chance to exit and drain before escalating.
+					forwardSIGHUP(session, endOfIODone)
 				} else { // member of terminatingSignals
 					print("\r")
-					syscall.Kill(commandPid, sig.(syscall.Signal))
+					session.Signal(sig.(syscall.Signal))
 					os.Exit(1)
 				}
 			}
 		}()
+	} else {
+		// No controlling terminal, so no in-band bytes to scan: OS signals only.
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, nonTerminalSignals...)
+		go func() {
+			for sig := range c {
+				switch sig {
+				case syscall.SIGCONT:
+					session.Signal(syscall.SIGCONT)
+				case syscall.SIGTSTP:
+					suspendSelf(session, func() {
+						if stderrIsTerminal {
+							ttyutils.MakeTerminalRaw(stderr.Fd())
+						}
+					})
+				case syscall.SIGHUP:
+					forwardSIGHUP(session, endOfIODone)
+				case syscall.SIGWINCH:
+					if stderrIsTerminal {
+						session.ResizeFromFile(messages.Stderr, stderr)
+					}
+					session.Signal(syscall.SIGWINCH)
+				default:
+					forwardAndAwaitDrain(session, sig.(syscall.Signal), endOfIODone)
+				}
+			}
+		}()
 	}
 
-	var exitStatus int = -1
-	if len(parts) > 2 {
-		exitStatus, err = strconv.Atoi(parts[0])
-		if err != nil {
-			slog.ErrorString(err.Error() + "\r")
-			return 1
-		}
-	}
+	exitStatus := session.InitialExitStatus
 
 	var endOfIO sync.WaitGroup
+	endOfIO.Add(3)
 
 	go func() {
-		endOfIO.Add(1)
-		for {
-			buf := make([]byte, 1024)
-			n, err := master.Read(buf)
-
-			if err == nil || (err == io.EOF && n > 0) {
-				output.Write(buf[:n])
-			} else {
-				endOfIO.Done()
-				break
-			}
-		}
+		defer endOfIO.Done()
+		copyPooled(output, session.Master)
 	}()
 
 	go func() {
-		endOfIO.Add(1)
-		for {
-			buf := make([]byte, 1024)
-			n, err := masterStderr.Read(buf)
-
-			if err == nil || (err == io.EOF && n > 0) {
-				stderr.Write(buf[:n])
-			} else {
-				endOfIO.Done()
-				break
-			}
-		}
+		defer endOfIO.Done()
+		copyPooled(stderr, session.MasterStderr)
 	}()
 
 	go func() {
-		endOfIO.Add(1)
-		buf := make([]byte, 8192)
-		for {
-			n, err := input.Read(buf)
-			if err != nil {
-				endOfIO.Done()
-				break
-			}
-			if isTerminal {
-				for i := 0; i < n; i++ {
-					switch buf[i] {
-					case sigInt:
-						syscall.Kill(commandPid, syscall.SIGINT)
-					case sigQuit:
-						syscall.Kill(commandPid, syscall.SIGQUIT)
-					case sigTstp:
-						syscall.Kill(commandPid, syscall.SIGTSTP)
-						syscall.Kill(os.Getpid(), syscall.SIGTSTP)
-					}
-				}
-			}
-			master.Write(buf[:n])
+		defer endOfIO.Done()
+		var in io.Reader = input
+		if isTerminal {
+			in = &signalScanningReader{r: input, session: session}
 		}
+		copyPooled(session.Master, in)
 	}()
 
 	endOfIO.Wait()
+	close(endOfIODone)
 
 	if exitStatus == -1 {
-		msg, err = usock.ReadMessage()
-		if err != nil {
-			slog.ErrorString(err.Error() + "\r")
-			return 1
-		}
-		parts := strings.Split(msg, "\000")
-		exitStatus, err = strconv.Atoi(parts[0])
+		exitStatus, err = session.Wait()
 		if err != nil {
 			slog.ErrorString(err.Error() + "\r")
 			return 1
@@ -261,32 +228,3 @@ func Run(args []string, input io.Reader, output *os.File, stderr *os.File) int {
 
 	return exitStatus
 }
-
-func sendCommandLineArguments(usock *unixsocket.Usock, args []string) error {
-	master, slave, err := unixsocket.Socketpair(syscall.SOCK_STREAM)
-	if err != nil {
-		return err
-	}
-	usock.WriteFD(int(slave.Fd()))
-	if err != nil {
-		return err
-	}
-	slave.Close()
-
-	go func() {
-		defer master.Close()
-		argAsBytes := []byte{}
-		for _, arg := range args[1:] {
-			argAsBytes = append(argAsBytes, []byte(arg)...)
-			argAsBytes = append(argAsBytes, byte(0))
-		}
-		_, err = master.Write(argAsBytes)
-		if err != nil {
-			slog.ErrorString("Could not send arguments across: " +
-				err.Error() + "\r")
-			return
-		}
-	}()
-
-	return nil
-}
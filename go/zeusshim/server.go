@@ -0,0 +1,224 @@
+// Package zeusshim exposes zeusclient.Session over gRPC so that embedders
+// without a controlling tty (editor integrations, CI harnesses, remote test
+// runners) can multiplex many zeus invocations over a single connection.
+package zeusshim
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/burke/zeus/go/messages"
+	"github.com/burke/zeus/go/zeusclient"
+	"github.com/burke/zeus/go/zeusshim/zeusshimpb"
+)
+
+// sessionEntry is what Server stores per session: the Session itself, plus
+// what reap needs to notice the remote command has exited without the
+// client ever calling Wait.
+type sessionEntry struct {
+	session *zeusclient.Session
+
+	done       chan struct{} // closed once exitStatus/waitErr are valid
+	exitStatus int
+	waitErr    error // set if entry.session.Wait() itself failed
+}
+
+// Server implements zeusshimpb.ZeusShimServer by driving one
+// zeusclient.Session per Exec call.
+type Server struct {
+	zeusshimpb.UnimplementedZeusShimServer
+
+	mu       sync.Mutex
+	sessions map[string]*sessionEntry
+	nextID   int
+}
+
+// NewServer returns an empty Server ready to be registered with a
+// grpc.Server via zeusshimpb.RegisterZeusShimServer.
+func NewServer() *Server {
+	return &Server{sessions: make(map[string]*sessionEntry)}
+}
+
+func (s *Server) Exec(ctx context.Context, req *zeusshimpb.ExecRequest) (*zeusshimpb.ExecResponse, error) {
+	session, err := zeusclient.NewSession(req.Args, req.UseTty)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &sessionEntry{session: session, done: make(chan struct{})}
+
+	s.mu.Lock()
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	s.sessions[id] = entry
+	s.mu.Unlock()
+
+	go s.reap(id, entry)
+
+	return &zeusshimpb.ExecResponse{
+		SessionId:  id,
+		CommandPid: int64(session.CommandPid),
+	}, nil
+}
+
+// reap waits for the remote command behind entry to exit, then closes its
+// fds and drops it from s.sessions, regardless of whether the client ever
+// calls Wait.
+func (s *Server) reap(id string, entry *sessionEntry) {
+	exitStatus := entry.session.InitialExitStatus
+	if exitStatus == -1 {
+		var err error
+		exitStatus, err = entry.session.Wait()
+		if err != nil {
+			entry.waitErr = err
+		}
+	}
+
+	entry.exitStatus = exitStatus
+	close(entry.done)
+	entry.session.Close()
+
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+}
+
+func (s *Server) Signal(ctx context.Context, req *zeusshimpb.SignalRequest) (*zeusshimpb.SignalResponse, error) {
+	session, err := s.session(req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := session.Signal(syscall.Signal(req.Signal)); err != nil {
+		return nil, err
+	}
+	return &zeusshimpb.SignalResponse{}, nil
+}
+
+func (s *Server) Resize(ctx context.Context, req *zeusshimpb.ResizeRequest) (*zeusshimpb.ResizeResponse, error) {
+	session, err := s.session(req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	which := messages.Stdout
+	if req.Stream == zeusshimpb.Stream_STDERR {
+		which = messages.Stderr
+	}
+	if err := session.Resize(which, uint16(req.Cols), uint16(req.Rows), 0, 0); err != nil {
+		return nil, err
+	}
+	return &zeusshimpb.ResizeResponse{}, nil
+}
+
+func (s *Server) Wait(ctx context.Context, req *zeusshimpb.WaitRequest) (*zeusshimpb.WaitResponse, error) {
+	entry, err := s.entry(req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if entry.waitErr != nil {
+		return nil, entry.waitErr
+	}
+	return &zeusshimpb.WaitResponse{ExitStatus: int32(entry.exitStatus)}, nil
+}
+
+// IO pumps stdin frames from the client to the session's master, and
+// stdout/stderr bytes from the session back to the client as frames, until
+// either side closes the stream.
+func (s *Server) IO(stream zeusshimpb.ZeusShim_IOServer) error {
+	frame, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	session, err := s.session(frame.SessionId)
+	if err != nil {
+		return err
+	}
+
+	// sendMu serializes stream.Send across the two pump goroutines below:
+	// grpc-go forbids calling Send concurrently on the same stream.
+	var sendMu sync.Mutex
+	var stopped int32
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		pumpToClient(stream, &sendMu, &stopped, frame.SessionId, zeusshimpb.Stream_STDOUT, session.Master)
+	}()
+	go func() {
+		defer wg.Done()
+		pumpToClient(stream, &sendMu, &stopped, frame.SessionId, zeusshimpb.Stream_STDERR, session.MasterStderr)
+	}()
+
+	if frame.Stream == zeusshimpb.Stream_STDIN && len(frame.Payload) > 0 {
+		session.Master.Write(frame.Payload)
+	}
+	for {
+		frame, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		if frame.Stream == zeusshimpb.Stream_STDIN {
+			session.Master.Write(frame.Payload)
+		}
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// pumpToClient reads from r and forwards each chunk to stream as a frame,
+// until r errors (the command exited) or stream.Send fails (the client went
+// away), in which case it flips *stopped so the sibling pump also gives up.
+func pumpToClient(stream zeusshimpb.ZeusShim_IOServer, sendMu *sync.Mutex, stopped *int32, sessionID string, which zeusshimpb.Stream, r io.Reader) {
+	buf := make([]byte, 32*1024)
+	for atomic.LoadInt32(stopped) == 0 {
+		n, err := r.Read(buf)
+		if n > 0 {
+			payload := make([]byte, n)
+			copy(payload, buf[:n])
+
+			sendMu.Lock()
+			sendErr := stream.Send(&zeusshimpb.IOFrame{SessionId: sessionID, Stream: which, Payload: payload})
+			sendMu.Unlock()
+
+			if sendErr != nil {
+				atomic.StoreInt32(stopped, 1)
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) entry(id string) (*sessionEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.sessions[id]
+	if !ok {
+		return nil, errors.New("zeusshim: unknown session " + id)
+	}
+	return entry, nil
+}
+
+func (s *Server) session(id string) (*zeusclient.Session, error) {
+	entry, err := s.entry(id)
+	if err != nil {
+		return nil, err
+	}
+	return entry.session, nil
+}
@@ -0,0 +1,49 @@
+package zeusshimpb
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// wireMessage is satisfied by every message type in shim.pb.go via its
+// hand-written Marshal/Unmarshal methods.
+type wireMessage interface {
+	Marshal() ([]byte, error)
+	Unmarshal([]byte) error
+}
+
+// CodecName is the grpc content-subtype Codec registers under. Real
+// protoc-gen-go output gets wire (de)serialization for free from grpc-go's
+// default "proto" codec via protoreflect; since these types are hand-written
+// instead, servers and clients must opt into Codec explicitly, e.g.
+// grpc.NewServer(grpc.ForceServerCodec(zeusshimpb.Codec)) and
+// grpc.Dial(addr, grpc.WithDefaultCallOptions(grpc.CallContentSubtype(zeusshimpb.CodecName))).
+const CodecName = "zeusshim"
+
+func init() {
+	encoding.RegisterCodec(Codec)
+}
+
+// Codec marshals zeusshimpb messages via their Marshal/Unmarshal methods.
+var Codec codec
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(wireMessage)
+	if !ok {
+		return nil, fmt.Errorf("zeusshimpb: %T does not implement Marshal/Unmarshal", v)
+	}
+	return m.Marshal()
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(wireMessage)
+	if !ok {
+		return fmt.Errorf("zeusshimpb: %T does not implement Marshal/Unmarshal", v)
+	}
+	return m.Unmarshal(data)
+}
+
+func (codec) Name() string { return CodecName }
@@ -0,0 +1,276 @@
+// Hand-written counterpart to shim.proto: no protoc toolchain is assumed to
+// be on hand, so these types are maintained by hand instead of generated.
+// Keep them in sync with shim.proto, and prefer real `protoc --go_out=.
+// --go-grpc_out=.` output over this file if the toolchain ever is available.
+// Marshal/Unmarshal implement the proto3 wire format directly (see wire.go)
+// rather than via reflection over the protobuf struct tags below, which are
+// kept only as documentation of each field's wire number.
+
+package zeusshimpb
+
+type Stream int32
+
+const (
+	Stream_STDIN  Stream = 0
+	Stream_STDOUT Stream = 1
+	Stream_STDERR Stream = 2
+)
+
+type ExecRequest struct {
+	Args   []string `protobuf:"bytes,1,rep,name=args,proto3" json:"args,omitempty"`
+	UseTty bool     `protobuf:"varint,2,opt,name=use_tty,json=useTty,proto3" json:"use_tty,omitempty"`
+}
+
+func (m *ExecRequest) Reset()         { *m = ExecRequest{} }
+func (m *ExecRequest) String() string { return "ExecRequest" }
+func (*ExecRequest) ProtoMessage()    {}
+
+func (m *ExecRequest) Marshal() ([]byte, error) {
+	var buf []byte
+	for _, a := range m.Args {
+		buf = appendStringField(buf, 1, a)
+	}
+	if m.UseTty {
+		buf = appendVarintField(buf, 2, 1)
+	}
+	return buf, nil
+}
+
+func (m *ExecRequest) Unmarshal(b []byte) error {
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	*m = ExecRequest{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.Args = append(m.Args, string(f.buf))
+		case 2:
+			m.UseTty = f.u64 != 0
+		}
+	}
+	return nil
+}
+
+type ExecResponse struct {
+	SessionId  string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	CommandPid int64  `protobuf:"varint,2,opt,name=command_pid,json=commandPid,proto3" json:"command_pid,omitempty"`
+}
+
+func (m *ExecResponse) Reset()         { *m = ExecResponse{} }
+func (m *ExecResponse) String() string { return "ExecResponse" }
+func (*ExecResponse) ProtoMessage()    {}
+
+func (m *ExecResponse) Marshal() ([]byte, error) {
+	var buf []byte
+	buf = appendStringField(buf, 1, m.SessionId)
+	buf = appendVarintField(buf, 2, uint64(m.CommandPid))
+	return buf, nil
+}
+
+func (m *ExecResponse) Unmarshal(b []byte) error {
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	*m = ExecResponse{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.SessionId = string(f.buf)
+		case 2:
+			m.CommandPid = int64(f.u64)
+		}
+	}
+	return nil
+}
+
+type IOFrame struct {
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Stream    Stream `protobuf:"varint,2,opt,name=stream,proto3,enum=zeusshim.Stream" json:"stream,omitempty"`
+	Payload   []byte `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *IOFrame) Reset()         { *m = IOFrame{} }
+func (m *IOFrame) String() string { return "IOFrame" }
+func (*IOFrame) ProtoMessage()    {}
+
+func (m *IOFrame) Marshal() ([]byte, error) {
+	var buf []byte
+	buf = appendStringField(buf, 1, m.SessionId)
+	buf = appendVarintField(buf, 2, uint64(m.Stream))
+	buf = appendBytesField(buf, 3, m.Payload)
+	return buf, nil
+}
+
+func (m *IOFrame) Unmarshal(b []byte) error {
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	*m = IOFrame{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.SessionId = string(f.buf)
+		case 2:
+			m.Stream = Stream(f.u64)
+		case 3:
+			m.Payload = append([]byte{}, f.buf...)
+		}
+	}
+	return nil
+}
+
+type SignalRequest struct {
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Signal    int32  `protobuf:"varint,2,opt,name=signal,proto3" json:"signal,omitempty"`
+}
+
+func (m *SignalRequest) Reset()         { *m = SignalRequest{} }
+func (m *SignalRequest) String() string { return "SignalRequest" }
+func (*SignalRequest) ProtoMessage()    {}
+
+func (m *SignalRequest) Marshal() ([]byte, error) {
+	var buf []byte
+	buf = appendStringField(buf, 1, m.SessionId)
+	buf = appendVarintField(buf, 2, uint64(uint32(m.Signal)))
+	return buf, nil
+}
+
+func (m *SignalRequest) Unmarshal(b []byte) error {
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	*m = SignalRequest{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.SessionId = string(f.buf)
+		case 2:
+			m.Signal = int32(f.u64)
+		}
+	}
+	return nil
+}
+
+type SignalResponse struct{}
+
+func (m *SignalResponse) Reset()         { *m = SignalResponse{} }
+func (m *SignalResponse) String() string { return "SignalResponse" }
+func (*SignalResponse) ProtoMessage()    {}
+
+func (m *SignalResponse) Marshal() ([]byte, error) { return nil, nil }
+
+func (m *SignalResponse) Unmarshal(b []byte) error {
+	*m = SignalResponse{}
+	return nil
+}
+
+type ResizeRequest struct {
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Stream    Stream `protobuf:"varint,2,opt,name=stream,proto3,enum=zeusshim.Stream" json:"stream,omitempty"`
+	Cols      uint32 `protobuf:"varint,3,opt,name=cols,proto3" json:"cols,omitempty"`
+	Rows      uint32 `protobuf:"varint,4,opt,name=rows,proto3" json:"rows,omitempty"`
+}
+
+func (m *ResizeRequest) Reset()         { *m = ResizeRequest{} }
+func (m *ResizeRequest) String() string { return "ResizeRequest" }
+func (*ResizeRequest) ProtoMessage()    {}
+
+func (m *ResizeRequest) Marshal() ([]byte, error) {
+	var buf []byte
+	buf = appendStringField(buf, 1, m.SessionId)
+	buf = appendVarintField(buf, 2, uint64(m.Stream))
+	buf = appendVarintField(buf, 3, uint64(m.Cols))
+	buf = appendVarintField(buf, 4, uint64(m.Rows))
+	return buf, nil
+}
+
+func (m *ResizeRequest) Unmarshal(b []byte) error {
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	*m = ResizeRequest{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.SessionId = string(f.buf)
+		case 2:
+			m.Stream = Stream(f.u64)
+		case 3:
+			m.Cols = uint32(f.u64)
+		case 4:
+			m.Rows = uint32(f.u64)
+		}
+	}
+	return nil
+}
+
+type ResizeResponse struct{}
+
+func (m *ResizeResponse) Reset()         { *m = ResizeResponse{} }
+func (m *ResizeResponse) String() string { return "ResizeResponse" }
+func (*ResizeResponse) ProtoMessage()    {}
+
+func (m *ResizeResponse) Marshal() ([]byte, error) { return nil, nil }
+
+func (m *ResizeResponse) Unmarshal(b []byte) error {
+	*m = ResizeResponse{}
+	return nil
+}
+
+type WaitRequest struct {
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+}
+
+func (m *WaitRequest) Reset()         { *m = WaitRequest{} }
+func (m *WaitRequest) String() string { return "WaitRequest" }
+func (*WaitRequest) ProtoMessage()    {}
+
+func (m *WaitRequest) Marshal() ([]byte, error) {
+	return appendStringField(nil, 1, m.SessionId), nil
+}
+
+func (m *WaitRequest) Unmarshal(b []byte) error {
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	*m = WaitRequest{}
+	for _, f := range fields {
+		if f.num == 1 {
+			m.SessionId = string(f.buf)
+		}
+	}
+	return nil
+}
+
+type WaitResponse struct {
+	ExitStatus int32 `protobuf:"varint,1,opt,name=exit_status,json=exitStatus,proto3" json:"exit_status,omitempty"`
+}
+
+func (m *WaitResponse) Reset()         { *m = WaitResponse{} }
+func (m *WaitResponse) String() string { return "WaitResponse" }
+func (*WaitResponse) ProtoMessage()    {}
+
+func (m *WaitResponse) Marshal() ([]byte, error) {
+	return appendVarintField(nil, 1, uint64(uint32(m.ExitStatus))), nil
+}
+
+func (m *WaitResponse) Unmarshal(b []byte) error {
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	*m = WaitResponse{}
+	for _, f := range fields {
+		if f.num == 1 {
+			m.ExitStatus = int32(f.u64)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// Hand-written counterpart to shim.proto, in the shape protoc-gen-go-grpc
+// would produce. See shim.pb.go and codec.go for why: no protoc toolchain
+// is assumed to be on hand, so the message types use a hand-rolled wire
+// codec instead of protoreflect-based marshaling.
+
+package zeusshimpb
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+)
+
+type ZeusShimClient interface {
+	Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (*ExecResponse, error)
+	IO(ctx context.Context, opts ...grpc.CallOption) (ZeusShim_IOClient, error)
+	Signal(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*SignalResponse, error)
+	Resize(ctx context.Context, in *ResizeRequest, opts ...grpc.CallOption) (*ResizeResponse, error)
+	Wait(ctx context.Context, in *WaitRequest, opts ...grpc.CallOption) (*WaitResponse, error)
+}
+
+type ZeusShim_IOClient interface {
+	Send(*IOFrame) error
+	Recv() (*IOFrame, error)
+	grpc.ClientStream
+}
+
+// ZeusShimServer is the server API for ZeusShim.
+type ZeusShimServer interface {
+	Exec(context.Context, *ExecRequest) (*ExecResponse, error)
+	IO(ZeusShim_IOServer) error
+	Signal(context.Context, *SignalRequest) (*SignalResponse, error)
+	Resize(context.Context, *ResizeRequest) (*ResizeResponse, error)
+	Wait(context.Context, *WaitRequest) (*WaitResponse, error)
+}
+
+type ZeusShim_IOServer interface {
+	Send(*IOFrame) error
+	Recv() (*IOFrame, error)
+	grpc.ServerStream
+}
+
+// UnimplementedZeusShimServer can be embedded to satisfy ZeusShimServer
+// without implementing every method, the same way protoc-gen-go-grpc does
+// for forward-compatible servers.
+type UnimplementedZeusShimServer struct{}
+
+func (UnimplementedZeusShimServer) Exec(context.Context, *ExecRequest) (*ExecResponse, error) {
+	return nil, errUnimplemented("Exec")
+}
+func (UnimplementedZeusShimServer) IO(ZeusShim_IOServer) error {
+	return errUnimplemented("IO")
+}
+func (UnimplementedZeusShimServer) Signal(context.Context, *SignalRequest) (*SignalResponse, error) {
+	return nil, errUnimplemented("Signal")
+}
+func (UnimplementedZeusShimServer) Resize(context.Context, *ResizeRequest) (*ResizeResponse, error) {
+	return nil, errUnimplemented("Resize")
+}
+func (UnimplementedZeusShimServer) Wait(context.Context, *WaitRequest) (*WaitResponse, error) {
+	return nil, errUnimplemented("Wait")
+}
+
+func errUnimplemented(method string) error {
+	return errors.New("zeusshim: method " + method + " not implemented")
+}
+
+func RegisterZeusShimServer(s grpc.ServiceRegistrar, srv ZeusShimServer) {
+	s.RegisterService(&ZeusShim_ServiceDesc, srv)
+}
+
+func _ZeusShim_Exec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ZeusShimServer).Exec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/zeusshim.ZeusShim/Exec"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ZeusShimServer).Exec(ctx, req.(*ExecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ZeusShim_Signal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ZeusShimServer).Signal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/zeusshim.ZeusShim/Signal"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ZeusShimServer).Signal(ctx, req.(*SignalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ZeusShim_Resize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ZeusShimServer).Resize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/zeusshim.ZeusShim/Resize"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ZeusShimServer).Resize(ctx, req.(*ResizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ZeusShim_Wait_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ZeusShimServer).Wait(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/zeusshim.ZeusShim/Wait"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ZeusShimServer).Wait(ctx, req.(*WaitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ZeusShim_IO_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ZeusShimServer).IO(&zeusShimIOServer{stream})
+}
+
+type zeusShimIOServer struct {
+	grpc.ServerStream
+}
+
+func (x *zeusShimIOServer) Send(m *IOFrame) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *zeusShimIOServer) Recv() (*IOFrame, error) {
+	m := new(IOFrame)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var ZeusShim_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "zeusshim.ZeusShim",
+	HandlerType: (*ZeusShimServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Exec", Handler: _ZeusShim_Exec_Handler},
+		{MethodName: "Signal", Handler: _ZeusShim_Signal_Handler},
+		{MethodName: "Resize", Handler: _ZeusShim_Resize_Handler},
+		{MethodName: "Wait", Handler: _ZeusShim_Wait_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IO",
+			Handler:       _ZeusShim_IO_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "go/zeusshim/shim.proto",
+}
@@ -0,0 +1,77 @@
+package zeusshimpb
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	cases := []wireMessage{
+		&ExecRequest{Args: []string{"testrb", "foo_test.rb"}, UseTty: true},
+		&ExecRequest{},
+		&ExecResponse{SessionId: "1", CommandPid: 4242},
+		&IOFrame{SessionId: "1", Stream: Stream_STDERR, Payload: []byte("hello\x00world")},
+		&SignalRequest{SessionId: "1", Signal: 9},
+		&SignalResponse{},
+		&ResizeRequest{SessionId: "1", Stream: Stream_STDOUT, Cols: 80, Rows: 24},
+		&ResizeResponse{},
+		&WaitRequest{SessionId: "1"},
+		&WaitResponse{ExitStatus: -1},
+	}
+
+	for _, want := range cases {
+		b, err := want.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal(%+v): %v", want, err)
+		}
+
+		got := reflect.New(reflect.TypeOf(want).Elem()).Interface().(wireMessage)
+		if err := got.Unmarshal(b); err != nil {
+			t.Fatalf("Unmarshal after Marshal(%+v): %v", want, err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip of %+v = %+v", want, got)
+		}
+	}
+}
+
+func TestIOFrameWireBytes(t *testing.T) {
+	f := &IOFrame{SessionId: "ab", Stream: Stream_STDOUT, Payload: []byte{1, 2}}
+	b, err := f.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	// field 1 (string "ab"): tag 0x0a, len 2, "ab"
+	// field 2 (varint 1):    tag 0x10, 1
+	// field 3 (bytes {1,2}): tag 0x1a, len 2, {1,2}
+	want := []byte{0x0a, 2, 'a', 'b', 0x10, 1, 0x1a, 2, 1, 2}
+	if !bytes.Equal(b, want) {
+		t.Errorf("Marshal(%+v) = % x, want % x", f, b, want)
+	}
+}
+
+func TestUnmarshalTruncated(t *testing.T) {
+	var r ExecResponse
+	if err := r.Unmarshal([]byte{0x0a, 5, 'h', 'i'}); err == nil {
+		t.Error("Unmarshal with truncated length-delimited field: got nil error, want error")
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	want := &WaitResponse{ExitStatus: 17}
+	b, err := Codec.Marshal(want)
+	if err != nil {
+		t.Fatalf("Codec.Marshal: %v", err)
+	}
+
+	var got WaitResponse
+	if err := Codec.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Codec.Unmarshal: %v", err)
+	}
+	if got != *want {
+		t.Errorf("Codec round trip = %+v, want %+v", got, want)
+	}
+}
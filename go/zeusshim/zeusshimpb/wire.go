@@ -0,0 +1,101 @@
+package zeusshimpb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Minimal proto3 wire-format helpers. shim.pb.go is hand-written (see its
+// header), so Marshal/Unmarshal on each message type are implemented
+// directly against these instead of relying on reflection over struct tags.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+func appendTag(buf []byte, field, wireType int) []byte {
+	return appendVarint(buf, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarintField(buf []byte, field int, v uint64) []byte {
+	return appendVarint(appendTag(buf, field, wireVarint), v)
+}
+
+func appendBytesField(buf []byte, field int, b []byte) []byte {
+	buf = appendTag(buf, field, wireBytes)
+	buf = appendVarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendStringField(buf []byte, field int, s string) []byte {
+	return appendBytesField(buf, field, []byte(s))
+}
+
+func readVarint(b []byte) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	for i, c := range b {
+		if shift >= 64 {
+			return 0, 0, errors.New("zeusshimpb: varint overflow")
+		}
+		v |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return v, i + 1, nil
+		}
+		shift += 7
+	}
+	return 0, 0, errors.New("zeusshimpb: truncated varint")
+}
+
+// wireField is one decoded tag+value pair: buf is set for wireBytes, u64
+// for wireVarint.
+type wireField struct {
+	num  int
+	wire int
+	u64  uint64
+	buf  []byte
+}
+
+func parseFields(b []byte) ([]wireField, error) {
+	var fields []wireField
+	for len(b) > 0 {
+		tag, n, err := readVarint(b)
+		if err != nil {
+			return nil, err
+		}
+		b = b[n:]
+		f := wireField{num: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case wireVarint:
+			v, n, err := readVarint(b)
+			if err != nil {
+				return nil, err
+			}
+			b = b[n:]
+			f.u64 = v
+		case wireBytes:
+			l, n, err := readVarint(b)
+			if err != nil {
+				return nil, err
+			}
+			b = b[n:]
+			if uint64(len(b)) < l {
+				return nil, errors.New("zeusshimpb: truncated length-delimited field")
+			}
+			f.buf = b[:l]
+			b = b[l:]
+		default:
+			return nil, fmt.Errorf("zeusshimpb: unsupported wire type %d", f.wire)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}